@@ -0,0 +1,130 @@
+// Package application builds the compute layer: an EC2 instance in a
+// private subnet fronted by an application load balancer in the public
+// subnets, with a target group and listener so the ALB actually forwards
+// traffic to the instance.
+package application
+
+import (
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/config"
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/network"
+	"github.com/pulumi/pulumi-aws/sdk/v4/go/aws/ec2"
+	"github.com/pulumi/pulumi-aws/sdk/v4/go/aws/lb"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Config describes the application's compute settings.
+type Config struct {
+	InstanceType string
+	AmiFilter    string
+	AmiOwner     string
+	Ingress      []config.IngressRule
+	Tags         pulumi.StringMap
+}
+
+// Application is the set of compute resources serving traffic.
+type Application struct {
+	SecurityGroup       *ec2.SecurityGroup
+	Server              *ec2.Instance
+	LoadBalancer        *lb.LoadBalancer
+	TargetGroup         *lb.TargetGroup
+	TargetGroupAttached *lb.TargetGroupAttachment
+	Listener            *lb.Listener
+}
+
+// New creates the security group, EC2 instance and ALB for the application,
+// placing the instance in the first private subnet and the ALB across the
+// public subnets supplied by net. The instance is registered with a target
+// group that the ALB's HTTP listener forwards to, so traffic reaching the
+// ALB is actually routed to the instance.
+func New(ctx *pulumi.Context, name string, net *network.Network, cfg Config) (*Application, error) {
+	group, err := ec2.NewSecurityGroup(ctx, name+"-secgrp", &ec2.SecurityGroupArgs{
+		VpcId:   net.VpcID,
+		Ingress: config.ToPulumiIngress(cfg.Ingress),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mostRecent := true
+	ami, err := ec2.LookupAmi(ctx, &ec2.LookupAmiArgs{
+		Filters: []ec2.GetAmiFilter{
+			{
+				Name:   "name",
+				Values: []string{cfg.AmiFilter},
+			},
+		},
+		Owners:     []string{cfg.AmiOwner},
+		MostRecent: &mostRecent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := ec2.NewInstance(ctx, name+"-server", &ec2.InstanceArgs{
+		InstanceType:        pulumi.String(cfg.InstanceType),
+		SubnetId:            net.PrivateSubnetIDs[0],
+		VpcSecurityGroupIds: pulumi.StringArray{group.ID()},
+		Ami:                 pulumi.String(ami.Id),
+		Tags:                cfg.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publicSubnetIDs := make(pulumi.StringArray, len(net.PublicSubnetIDs))
+	for i, id := range net.PublicSubnetIDs {
+		publicSubnetIDs[i] = id.ToStringOutput()
+	}
+
+	balancer, err := lb.NewLoadBalancer(ctx, name+"-alb", &lb.LoadBalancerArgs{
+		LoadBalancerType: pulumi.String("application"),
+		Internal:         pulumi.Bool(false),
+		SecurityGroups:   pulumi.StringArray{group.ID()},
+		Subnets:          publicSubnetIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targetGroup, err := lb.NewTargetGroup(ctx, name+"-tg", &lb.TargetGroupArgs{
+		Port:     pulumi.Int(80),
+		Protocol: pulumi.String("HTTP"),
+		VpcId:    net.VpcID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := lb.NewTargetGroupAttachment(ctx, name+"-tg-attachment", &lb.TargetGroupAttachmentArgs{
+		TargetGroupArn: targetGroup.Arn,
+		TargetId:       server.ID(),
+		Port:           pulumi.Int(80),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := lb.NewListener(ctx, name+"-listener", &lb.ListenerArgs{
+		LoadBalancerArn: balancer.Arn,
+		Port:            pulumi.Int(80),
+		Protocol:        pulumi.String("HTTP"),
+		DefaultActions: lb.ListenerDefaultActionArray{
+			lb.ListenerDefaultActionArgs{
+				Type:           pulumi.String("forward"),
+				TargetGroupArn: targetGroup.Arn,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Application{
+		SecurityGroup:       group,
+		Server:              server,
+		LoadBalancer:        balancer,
+		TargetGroup:         targetGroup,
+		TargetGroupAttached: attachment,
+		Listener:            listener,
+	}, nil
+}
@@ -0,0 +1,113 @@
+// Package config loads security group ingress rules from external
+// JSON or YAML files, so a deployment's ingress rules can be driven by
+// config instead of being hard-coded into the Go program.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pulumi/pulumi-aws/sdk/v4/go/aws/ec2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"gopkg.in/yaml.v3"
+)
+
+// validProtocols is the set of protocol values a rule may use: "tcp",
+// "udp", "icmp", or "-1" for all protocols. Other protocol names and bare
+// protocol numbers are rejected.
+var validProtocols = map[string]bool{
+	"tcp":  true,
+	"udp":  true,
+	"icmp": true,
+	"-1":   true,
+}
+
+// IngressRule is the external, file-friendly schema for a security group
+// ingress rule:
+//
+//	[{"protocol": "tcp", "from_port": 22, "to_port": 22, "cidr_blocks": ["10.0.0.0/8"], "description": "ssh"}]
+type IngressRule struct {
+	Protocol    string   `json:"protocol" yaml:"protocol"`
+	FromPort    int      `json:"from_port" yaml:"from_port"`
+	ToPort      int      `json:"to_port" yaml:"to_port"`
+	CidrBlocks  []string `json:"cidr_blocks" yaml:"cidr_blocks"`
+	Description string   `json:"description" yaml:"description"`
+}
+
+// Validate checks that a rule has a recognized protocol, in-range ports and
+// parseable CIDR blocks.
+func (r IngressRule) Validate() error {
+	if !validProtocols[strings.ToLower(r.Protocol)] {
+		return fmt.Errorf("config: unsupported protocol %q", r.Protocol)
+	}
+	if r.FromPort < 0 || r.FromPort > 65535 {
+		return fmt.Errorf("config: from_port %d out of range 0-65535", r.FromPort)
+	}
+	if r.ToPort < 0 || r.ToPort > 65535 {
+		return fmt.Errorf("config: to_port %d out of range 0-65535", r.ToPort)
+	}
+	if r.FromPort > r.ToPort {
+		return fmt.Errorf("config: from_port %d is greater than to_port %d", r.FromPort, r.ToPort)
+	}
+	if len(r.CidrBlocks) == 0 {
+		return fmt.Errorf("config: cidr_blocks must not be empty")
+	}
+	for _, cidr := range r.CidrBlocks {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("config: invalid cidr_block %q: %w", cidr, err)
+		}
+	}
+	return nil
+}
+
+// LoadIngressRules reads and validates a set of ingress rules from a JSON
+// or YAML file, selected by its extension (.json, or .yaml/.yml).
+func LoadIngressRules(path string) ([]IngressRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: reading %s: %w", path, err)
+	}
+
+	var rules []IngressRule
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return nil, fmt.Errorf("config: parsing %s as JSON: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &rules); err != nil {
+			return nil, fmt.Errorf("config: parsing %s as YAML: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("config: unsupported extension %q for ingress rules file %s", ext, path)
+	}
+
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("config: rule %d in %s: %w", i, path, err)
+		}
+	}
+
+	return rules, nil
+}
+
+// ToPulumiIngress converts validated ingress rules into the
+// ec2.SecurityGroupIngressArray pulumi-aws v4's SecurityGroupArgs.Ingress
+// field expects.
+func ToPulumiIngress(rules []IngressRule) ec2.SecurityGroupIngressArray {
+	out := make(ec2.SecurityGroupIngressArray, len(rules))
+	for i, r := range rules {
+		out[i] = ec2.SecurityGroupIngressArgs{
+			Protocol:    pulumi.String(r.Protocol),
+			FromPort:    pulumi.Int(r.FromPort),
+			ToPort:      pulumi.Int(r.ToPort),
+			CidrBlocks:  pulumi.ToStringArray(r.CidrBlocks),
+			Description: pulumi.String(r.Description),
+		}
+	}
+	return out
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi-aws/sdk/v4/go/aws/ec2"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func TestIngressRuleValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		rule    IngressRule
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			rule: IngressRule{Protocol: "tcp", FromPort: 80, ToPort: 80, CidrBlocks: []string{"0.0.0.0/0"}},
+		},
+		{
+			name:    "bad-protocol",
+			rule:    IngressRule{Protocol: "ftp", FromPort: 21, ToPort: 21, CidrBlocks: []string{"0.0.0.0/0"}},
+			wantErr: true,
+		},
+		{
+			name:    "from-port-out-of-range",
+			rule:    IngressRule{Protocol: "tcp", FromPort: -1, ToPort: 80, CidrBlocks: []string{"0.0.0.0/0"}},
+			wantErr: true,
+		},
+		{
+			name:    "to-port-out-of-range",
+			rule:    IngressRule{Protocol: "tcp", FromPort: 80, ToPort: 70000, CidrBlocks: []string{"0.0.0.0/0"}},
+			wantErr: true,
+		},
+		{
+			name:    "from-greater-than-to",
+			rule:    IngressRule{Protocol: "tcp", FromPort: 443, ToPort: 80, CidrBlocks: []string{"0.0.0.0/0"}},
+			wantErr: true,
+		},
+		{
+			name:    "no-cidr-blocks",
+			rule:    IngressRule{Protocol: "tcp", FromPort: 80, ToPort: 80},
+			wantErr: true,
+		},
+		{
+			name:    "malformed-cidr",
+			rule:    IngressRule{Protocol: "tcp", FromPort: 80, ToPort: 80, CidrBlocks: []string{"not-a-cidr"}},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.rule.Validate()
+			if (err != nil) != c.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadIngressRulesJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	writeFile(t, path, `[
+		{"protocol": "tcp", "from_port": 80, "to_port": 80, "cidr_blocks": ["0.0.0.0/0"], "description": "http"},
+		{"protocol": "tcp", "from_port": 443, "to_port": 443, "cidr_blocks": ["0.0.0.0/0"], "description": "https"}
+	]`)
+
+	rules, err := LoadIngressRules(path)
+	if err != nil {
+		t.Fatalf("LoadIngressRules() error = %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	if rules[0].Description != "http" || rules[1].FromPort != 443 {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+}
+
+func TestLoadIngressRulesYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	writeFile(t, path, `
+- protocol: tcp
+  from_port: 22
+  to_port: 22
+  cidr_blocks: ["10.0.0.0/8"]
+  description: ssh
+`)
+
+	rules, err := LoadIngressRules(path)
+	if err != nil {
+		t.Fatalf("LoadIngressRules() error = %v", err)
+	}
+	if len(rules) != 1 || rules[0].FromPort != 22 {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+}
+
+func TestLoadIngressRulesErrors(t *testing.T) {
+	t.Run("unsupported-extension", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rules.txt")
+		writeFile(t, path, `[]`)
+		if _, err := LoadIngressRules(path); err == nil {
+			t.Fatal("expected an error for an unsupported extension")
+		}
+	})
+
+	t.Run("malformed-json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		writeFile(t, path, `not json`)
+		if _, err := LoadIngressRules(path); err == nil {
+			t.Fatal("expected an error for malformed JSON")
+		}
+	})
+
+	t.Run("fails-validation", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		writeFile(t, path, `[{"protocol": "ftp", "from_port": 21, "to_port": 21, "cidr_blocks": ["0.0.0.0/0"]}]`)
+		if _, err := LoadIngressRules(path); err == nil {
+			t.Fatal("expected a validation error")
+		}
+	})
+
+	t.Run("missing-file", func(t *testing.T) {
+		if _, err := LoadIngressRules(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+			t.Fatal("expected an error for a missing file")
+		}
+	})
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+type mocks int
+
+func (mocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (mocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+// TestToPulumiIngress attaches the built ec2.SecurityGroupIngressArray to a
+// real SecurityGroup resource under a mock monitor and checks the fields
+// once resolved, the same way main_test.go verifies resources elsewhere in
+// this repo.
+func TestToPulumiIngress(t *testing.T) {
+	rules := []IngressRule{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80, CidrBlocks: []string{"0.0.0.0/0"}, Description: "http"},
+		{Protocol: "tcp", FromPort: 443, ToPort: 443, CidrBlocks: []string{"0.0.0.0/0", "10.0.0.0/8"}, Description: "https"},
+	}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		group, err := ec2.NewSecurityGroup(ctx, "test-secgrp", &ec2.SecurityGroupArgs{
+			Ingress: ToPulumiIngress(rules),
+		})
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		group.Ingress.ApplyT(func(ingress []ec2.SecurityGroupIngress) error {
+			defer wg.Done()
+			if len(ingress) != len(rules) {
+				t.Fatalf("got %d ingress rules, want %d", len(ingress), len(rules))
+			}
+			for i, rule := range rules {
+				got := ingress[i]
+				if got.Protocol != rule.Protocol || got.FromPort != rule.FromPort || got.ToPort != rule.ToPort {
+					t.Errorf("rule %d = %+v, want %+v", i, got, rule)
+				}
+				if len(got.CidrBlocks) != len(rule.CidrBlocks) {
+					t.Errorf("rule %d CidrBlocks = %v, want %v", i, got.CidrBlocks, rule.CidrBlocks)
+				}
+			}
+			return nil
+		})
+		wg.Wait()
+		return nil
+	}, pulumi.WithMocks("unit-test-project", "unit-test-stack", mocks(0)))
+
+	if err != nil {
+		t.Fatalf("pulumi.RunErr: %v", err)
+	}
+}
@@ -1,65 +1,80 @@
 package main
 
 import (
-	"github.com/pulumi/pulumi-aws/sdk/v4/go/aws/ec2"
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/application"
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/config"
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/network"
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/stacks"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
-type infrastructure struct {
-	group  *ec2.SecurityGroup
-	server *ec2.Instance
-}
-
-func createInfrastructure(ctx *pulumi.Context) (*infrastructure, error) {
-	group, err := ec2.NewSecurityGroup(ctx, "web-secgrp", &ec2.SecurityGroupArgs{
-		Ingress: ec2.SecurityGroupIngressArray{
-			// Uncomment to fail a test:
-			// ec2.SecurityGroupIngressArgs{
-			// 	Protocol:   pulumi.String("tcp"),
-			// 	FromPort:   pulumi.Int(22),
-			// 	ToPort:     pulumi.Int(22),
-			// 	CidrBlocks: pulumi.StringArray{pulumi.String("0.0.0.0/0")},
-			// },
-			ec2.SecurityGroupIngressArgs{
-				Protocol:   pulumi.String("tcp"),
-				FromPort:   pulumi.Int(80),
-				ToPort:     pulumi.Int(80),
-				CidrBlocks: pulumi.StringArray{pulumi.String("0.0.0.0/0")},
-			},
+// defaultConfig mirrors the topology the original single-file program
+// created: one webserver behind a security group that only allows HTTP,
+// now placed in a private subnet behind an ALB across two availability
+// zones. Per-environment stack overrides are layered on top of it in
+// applyEnv.
+func defaultConfig() Config {
+	return Config{
+		Network: network.Config{
+			CidrBlock:          "10.0.0.0/16",
+			AvailabilityZones:  []string{"us-east-1a", "us-east-1b"},
+			PublicSubnetCidrs:  []string{"10.0.1.0/24", "10.0.2.0/24"},
+			PrivateSubnetCidrs: []string{"10.0.101.0/24", "10.0.102.0/24"},
 		},
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	mostRecent := true
-	ami, err := ec2.LookupAmi(ctx, &ec2.LookupAmiArgs{
-		Filters: []ec2.GetAmiFilter{
-			{
-				Name:   "name",
-				Values: []string{"ubuntu/images/hvm-ssd/ubuntu-bionic-18.04-amd64-server-*"},
+		Application: application.Config{
+			InstanceType: "t2.micro",
+			AmiFilter:    "ubuntu/images/hvm-ssd/ubuntu-bionic-18.04-amd64-server-*",
+			AmiOwner:     "137112412989",
+			Ingress: []config.IngressRule{
+				{Protocol: "tcp", FromPort: 80, ToPort: 80, CidrBlocks: []string{"0.0.0.0/0"}},
 			},
+			Tags: pulumi.StringMap{"Name": pulumi.String("webserver")},
 		},
-		Owners:     []string{"137112412989"},
-		MostRecent: &mostRecent,
-	})
-	if err != nil {
-		return nil, err
 	}
+}
 
-	server, err := ec2.NewInstance(ctx, "web-server-www", &ec2.InstanceArgs{
-		InstanceType:        pulumi.String("t2-micro"),
-		VpcSecurityGroupIds: pulumi.StringArray{group.ID()}, // reference the group object above
-		Ami:                 pulumi.String(ami.Id),
-		// Comment out to fail a test:
-		Tags: pulumi.StringMap{"Name": pulumi.String("webserver")},
-	})
-	if err != nil {
-		return nil, err
+// applyEnv layers a stack's per-environment overrides on top of cfg. A
+// zero-valued field on env leaves cfg's default untouched.
+func applyEnv(cfg Config, env stacks.EnvConfig) Config {
+	if env.InstanceType != "" {
+		cfg.Application.InstanceType = env.InstanceType
 	}
+	if env.AmiFilter != "" {
+		cfg.Application.AmiFilter = env.AmiFilter
+	}
+	if env.AmiOwner != "" {
+		cfg.Application.AmiOwner = env.AmiOwner
+	}
+	if env.TagPrefix != "" {
+		cfg.Application.Tags = pulumi.StringMap{"Name": pulumi.String(env.TagPrefix + "-webserver")}
+	}
+	if len(env.Ingress) > 0 {
+		cfg.Application.Ingress = env.Ingress
+	}
+	return cfg
+}
 
-	return &infrastructure{
-		group:  group,
-		server: server,
-	}, nil
+func main() {
+	pulumi.Run(func(ctx *pulumi.Context) error {
+		env, err := stacks.Resolve(ctx, ctx.Stack())
+		if err != nil {
+			return err
+		}
+
+		stack, err := NewStack(ctx, "web", applyEnv(defaultConfig(), env))
+		if err != nil {
+			return err
+		}
+
+		ctx.Export("vpcId", stack.Network.VpcID)
+
+		outputs := stacks.Outputs{
+			PublicIP:         stack.Application.Server.PublicIp,
+			PublicDNS:        stack.Application.Server.PublicDns,
+			SecurityGroupArn: stack.Application.SecurityGroup.Arn,
+			InstanceID:       stack.Application.Server.ID(),
+		}
+		outputs.Export(ctx)
+		return nil
+	})
 }
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/network"
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/policy"
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/stacks"
+	"github.com/pulumi/pulumi-aws/sdk/v4/go/aws/ec2"
+	"github.com/pulumi/pulumi-aws/sdk/v4/go/aws/lb"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// mocks implements pulumi.MockResourceMonitor so the stack can be built
+// without talking to real AWS, per the Pulumi unit-testing guide.
+type mocks int
+
+func (mocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	outputs := args.Inputs.Mappable()
+
+	if args.TypeToken == "aws:ec2/instance:Instance" {
+		outputs["publicIp"] = "203.0.113.12"
+		outputs["publicDns"] = "ec2-203-0-113-12.compute-1.amazonaws.com"
+	}
+
+	return args.Name + "_id", resource.NewPropertyMapFromMap(outputs), nil
+}
+
+func (mocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	if args.Token == "aws:index/getAmi:getAmi" {
+		return resource.NewPropertyMapFromMap(map[string]interface{}{
+			"id": "ami-0123456789abcdef0",
+		}), nil
+	}
+	return args.Args, nil
+}
+
+// runStack builds cfg against the mock monitor and hands the result to f
+// once all outputs have resolved.
+func runStack(t *testing.T, cfg Config, f func(*Stack)) {
+	t.Helper()
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		stack, err := NewStack(ctx, "web", cfg)
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		pulumi.All(
+			stack.Application.Server.Tags,
+			stack.Application.Server.UserData,
+			stack.Application.SecurityGroup.Ingress,
+			stack.Application.Server.SubnetId,
+			stack.Application.LoadBalancer.Subnets,
+			stack.Application.TargetGroupAttached.TargetGroupArn,
+			stack.Application.Listener.DefaultActions,
+		).ApplyT(func(_ []interface{}) error {
+			defer wg.Done()
+			f(stack)
+			return nil
+		})
+
+		wg.Wait()
+		return nil
+	}, pulumi.WithMocks("unit-test-project", "unit-test-stack", mocks(0)))
+
+	if err != nil {
+		t.Fatalf("NewStack: %v", err)
+	}
+}
+
+func TestApplicationPolicy(t *testing.T) {
+	runStack(t, defaultConfig(), func(stack *Stack) {
+		stack.Application.Server.Tags.ApplyT(func(tags map[string]string) error {
+			facts := policy.InstanceFacts{Tags: tags}
+			if violations := policy.CheckInstance("web-server", facts, policy.RequireNameTag); len(violations) != 0 {
+				t.Errorf("unexpected instance policy violations: %v", violations)
+			}
+			return nil
+		})
+
+		stack.Application.SecurityGroup.Ingress.ApplyT(func(ingress []ec2.SecurityGroupIngress) error {
+			var rules []policy.IngressRule
+			for _, i := range ingress {
+				rules = append(rules, policy.IngressRule{
+					Protocol:       i.Protocol,
+					FromPort:       i.FromPort,
+					ToPort:         i.ToPort,
+					CidrBlocks:     i.CidrBlocks,
+					Ipv6CidrBlocks: i.Ipv6CidrBlocks,
+				})
+			}
+			if violations := policy.CheckIngress("web-secgrp", rules); len(violations) != 0 {
+				t.Errorf("unexpected ingress policy violations: %v", violations)
+			}
+			return nil
+		})
+	})
+}
+
+func TestNetworkTopology(t *testing.T) {
+	if err := network.Validate(defaultConfig().Network); err != nil {
+		t.Fatalf("defaultConfig().Network is invalid: %v", err)
+	}
+
+	runStack(t, defaultConfig(), func(stack *Stack) {
+		pulumi.All(stack.Application.Server.SubnetId, stack.Network.PrivateSubnetIDs[0]).ApplyT(
+			func(args []interface{}) error {
+				// args[0] resolves as a plain string (InstanceArgs.SubnetId is a
+				// pulumi.StringInput) while args[1] resolves as a pulumi.ID
+				// (IDOutput.ApplyT yields pulumi.ID), so compare their string
+				// forms rather than the boxed values.
+				serverSubnet, privateSubnet := fmt.Sprint(args[0]), fmt.Sprint(args[1])
+				if serverSubnet != privateSubnet {
+					t.Errorf("server subnet = %v, want private subnet %v", serverSubnet, privateSubnet)
+				}
+				return nil
+			},
+		)
+
+		inputs := make([]interface{}, 0, len(stack.Network.PublicSubnetIDs)+1)
+		for _, id := range stack.Network.PublicSubnetIDs {
+			inputs = append(inputs, id)
+		}
+		inputs = append(inputs, stack.Application.LoadBalancer.Subnets)
+
+		pulumi.All(inputs...).ApplyT(func(args []interface{}) error {
+			wantCount := len(args) - 1
+			gotSubnets, _ := args[wantCount].([]string)
+			if len(gotSubnets) != wantCount {
+				t.Errorf("ALB has %d subnets, want %d", len(gotSubnets), wantCount)
+				return nil
+			}
+			for i, got := range gotSubnets {
+				// args[i] resolves as a pulumi.ID, not a string, so format
+				// rather than type-assert it.
+				want := fmt.Sprint(args[i])
+				if got != want {
+					t.Errorf("ALB subnet[%d] = %q, want %q", i, got, want)
+				}
+			}
+			return nil
+		})
+
+		pulumi.All(
+			stack.Application.TargetGroupAttached.TargetId,
+			stack.Application.Server.ID(),
+			stack.Application.TargetGroupAttached.TargetGroupArn,
+			stack.Application.TargetGroup.Arn,
+			stack.Application.Listener.DefaultActions,
+		).ApplyT(func(args []interface{}) error {
+			if gotTarget, wantTarget := fmt.Sprint(args[0]), fmt.Sprint(args[1]); gotTarget != wantTarget {
+				t.Errorf("target group attachment targetId = %v, want instance ID %v", gotTarget, wantTarget)
+			}
+			if gotArn, wantArn := fmt.Sprint(args[2]), fmt.Sprint(args[3]); gotArn != wantArn {
+				t.Errorf("target group attachment targetGroupArn = %v, want %v", gotArn, wantArn)
+			}
+
+			actions, _ := args[4].([]lb.ListenerDefaultAction)
+			if len(actions) != 1 || actions[0].TargetGroupArn == nil {
+				t.Fatalf("listener has %d default actions, want 1 forwarding to a target group", len(actions))
+			}
+			if got, want := *actions[0].TargetGroupArn, fmt.Sprint(args[3]); got != want {
+				t.Errorf("listener forwards to target group %v, want %v", got, want)
+			}
+			return nil
+		})
+	})
+}
+
+// checkStack validates the network config and runs the name-tag and
+// ingress policy checks above against a named environment's
+// stacks/<name>.yaml overrides layered onto defaultConfig, so every
+// environment is covered by the same `go test ./...` invocation. The subnet
+// placement checks of TestNetworkTopology are not repeated here.
+func checkStack(t *testing.T, name string) {
+	t.Helper()
+
+	env, err := stacks.LoadFile(name)
+	if err != nil {
+		t.Fatalf("stacks.LoadFile(%q): %v", name, err)
+	}
+
+	cfg := applyEnv(defaultConfig(), env)
+	if err := network.Validate(cfg.Network); err != nil {
+		t.Fatalf("%s stack has an invalid network config: %v", name, err)
+	}
+
+	runStack(t, cfg, func(stack *Stack) {
+		stack.Application.Server.Tags.ApplyT(func(tags map[string]string) error {
+			facts := policy.InstanceFacts{Tags: tags}
+			if violations := policy.CheckInstance(name+"-server", facts, policy.RequireNameTag); len(violations) != 0 {
+				t.Errorf("%s: unexpected instance policy violations: %v", name, violations)
+			}
+			return nil
+		})
+
+		stack.Application.SecurityGroup.Ingress.ApplyT(func(ingress []ec2.SecurityGroupIngress) error {
+			var rules []policy.IngressRule
+			for _, i := range ingress {
+				rules = append(rules, policy.IngressRule{
+					Protocol:       i.Protocol,
+					FromPort:       i.FromPort,
+					ToPort:         i.ToPort,
+					CidrBlocks:     i.CidrBlocks,
+					Ipv6CidrBlocks: i.Ipv6CidrBlocks,
+				})
+			}
+			if violations := policy.CheckIngress(name+"-secgrp", rules); len(violations) != 0 {
+				t.Errorf("%s: unexpected ingress policy violations: %v", name, violations)
+			}
+			return nil
+		})
+	})
+}
+
+func TestDevStack(t *testing.T) {
+	checkStack(t, "dev")
+}
+
+func TestProdStack(t *testing.T) {
+	checkStack(t, "prod")
+}
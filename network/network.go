@@ -0,0 +1,136 @@
+// Package network builds the VPC topology the application layer is
+// deployed into: a public/private subnet pair per availability zone, an
+// internet gateway, and the route tables that give the public subnets
+// internet access.
+package network
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/pulumi/pulumi-aws/sdk/v4/go/aws/ec2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Config describes the VPC topology to build.
+type Config struct {
+	// CidrBlock is the CIDR range for the VPC itself, e.g. "10.0.0.0/16".
+	CidrBlock string
+	// PublicSubnetCidrs has one entry per availability zone.
+	PublicSubnetCidrs []string
+	// PrivateSubnetCidrs has one entry per availability zone.
+	PrivateSubnetCidrs []string
+	// AvailabilityZones has one entry per subnet pair, aligned by index
+	// with PublicSubnetCidrs/PrivateSubnetCidrs.
+	AvailabilityZones []string
+}
+
+// Network is the set of networking resources the application layer is
+// wired into.
+type Network struct {
+	VpcID            pulumi.IDOutput
+	PublicSubnetIDs  []pulumi.IDOutput
+	PrivateSubnetIDs []pulumi.IDOutput
+}
+
+// Validate checks that the subnet CIDRs are well-formed and don't overlap
+// each other.
+func Validate(cfg Config) error {
+	if len(cfg.PublicSubnetCidrs) != len(cfg.PrivateSubnetCidrs) || len(cfg.PublicSubnetCidrs) != len(cfg.AvailabilityZones) {
+		return fmt.Errorf("network: PublicSubnetCidrs, PrivateSubnetCidrs and AvailabilityZones must have the same length")
+	}
+
+	var nets []*net.IPNet
+	all := append(append([]string{}, cfg.PublicSubnetCidrs...), cfg.PrivateSubnetCidrs...)
+	for _, cidr := range all {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("network: invalid subnet CIDR %q: %w", cidr, err)
+		}
+		for _, other := range nets {
+			if ipNet.Contains(other.IP) || other.Contains(ipNet.IP) {
+				return fmt.Errorf("network: subnet CIDR %q overlaps %q", cidr, other)
+			}
+		}
+		nets = append(nets, ipNet)
+	}
+
+	return nil
+}
+
+// New creates a VPC, a public/private subnet pair per configured
+// availability zone, an internet gateway, and the route tables needed for
+// the public subnets to reach it.
+func New(ctx *pulumi.Context, name string, cfg Config) (*Network, error) {
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	vpc, err := ec2.NewVpc(ctx, name, &ec2.VpcArgs{
+		CidrBlock:          pulumi.String(cfg.CidrBlock),
+		EnableDnsHostnames: pulumi.Bool(true),
+		Tags:               pulumi.StringMap{"Name": pulumi.String(name)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	igw, err := ec2.NewInternetGateway(ctx, name, &ec2.InternetGatewayArgs{
+		VpcId: vpc.ID(),
+		Tags:  pulumi.StringMap{"Name": pulumi.String(name)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publicRouteTable, err := ec2.NewRouteTable(ctx, fmt.Sprintf("%s-public", name), &ec2.RouteTableArgs{
+		VpcId: vpc.ID(),
+		Routes: ec2.RouteTableRouteArray{
+			ec2.RouteTableRouteArgs{
+				CidrBlock: pulumi.String("0.0.0.0/0"),
+				GatewayId: igw.ID(),
+			},
+		},
+		Tags: pulumi.StringMap{"Name": pulumi.String(fmt.Sprintf("%s-public", name))},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nw := &Network{VpcID: vpc.ID()}
+
+	for i, az := range cfg.AvailabilityZones {
+		publicSubnet, err := ec2.NewSubnet(ctx, fmt.Sprintf("%s-public-%d", name, i), &ec2.SubnetArgs{
+			VpcId:               vpc.ID(),
+			CidrBlock:           pulumi.String(cfg.PublicSubnetCidrs[i]),
+			AvailabilityZone:    pulumi.String(az),
+			MapPublicIpOnLaunch: pulumi.Bool(true),
+			Tags:                pulumi.StringMap{"Name": pulumi.String(fmt.Sprintf("%s-public-%d", name, i))},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := ec2.NewRouteTableAssociation(ctx, fmt.Sprintf("%s-public-%d", name, i), &ec2.RouteTableAssociationArgs{
+			SubnetId:     publicSubnet.ID(),
+			RouteTableId: publicRouteTable.ID(),
+		}); err != nil {
+			return nil, err
+		}
+
+		privateSubnet, err := ec2.NewSubnet(ctx, fmt.Sprintf("%s-private-%d", name, i), &ec2.SubnetArgs{
+			VpcId:            vpc.ID(),
+			CidrBlock:        pulumi.String(cfg.PrivateSubnetCidrs[i]),
+			AvailabilityZone: pulumi.String(az),
+			Tags:             pulumi.StringMap{"Name": pulumi.String(fmt.Sprintf("%s-private-%d", name, i))},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		nw.PublicSubnetIDs = append(nw.PublicSubnetIDs, publicSubnet.ID())
+		nw.PrivateSubnetIDs = append(nw.PrivateSubnetIDs, privateSubnet.ID())
+	}
+
+	return nw, nil
+}
@@ -0,0 +1,57 @@
+package network
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{
+			name: "non-overlapping",
+			cfg: Config{
+				AvailabilityZones:  []string{"us-east-1a", "us-east-1b"},
+				PublicSubnetCidrs:  []string{"10.0.1.0/24", "10.0.2.0/24"},
+				PrivateSubnetCidrs: []string{"10.0.101.0/24", "10.0.102.0/24"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "overlapping-public-private",
+			cfg: Config{
+				AvailabilityZones:  []string{"us-east-1a"},
+				PublicSubnetCidrs:  []string{"10.0.1.0/24"},
+				PrivateSubnetCidrs: []string{"10.0.1.128/25"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mismatched-lengths",
+			cfg: Config{
+				AvailabilityZones:  []string{"us-east-1a", "us-east-1b"},
+				PublicSubnetCidrs:  []string{"10.0.1.0/24"},
+				PrivateSubnetCidrs: []string{"10.0.101.0/24"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid-cidr",
+			cfg: Config{
+				AvailabilityZones:  []string{"us-east-1a"},
+				PublicSubnetCidrs:  []string{"not-a-cidr"},
+				PrivateSubnetCidrs: []string{"10.0.101.0/24"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Validate(c.cfg)
+			if (err != nil) != c.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
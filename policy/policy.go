@@ -0,0 +1,133 @@
+// Package policy implements the infrastructure policy checks described in
+// the Pulumi unit-testing guide: every instance must be named, instances
+// must not ship cloud-init UserData, and security groups must not expose
+// SSH to the world. The checks operate on plain Go values so they can be
+// exercised directly in table-driven tests and also run against resolved
+// Pulumi outputs from a mock deployment.
+package policy
+
+import "fmt"
+
+// Violation describes a single policy rule failure.
+type Violation struct {
+	Rule     string
+	Resource string
+	Message  string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s: %s", v.Rule, v.Resource, v.Message)
+}
+
+// InstanceFacts is the subset of an ec2.Instance's resolved state that the
+// instance rules need.
+type InstanceFacts struct {
+	Tags     map[string]string
+	UserData string
+}
+
+// IngressRule is the resolved form of an ec2.SecurityGroupIngressArgs entry.
+type IngressRule struct {
+	Protocol       string
+	FromPort       int
+	ToPort         int
+	CidrBlocks     []string
+	Ipv6CidrBlocks []string
+}
+
+// InstanceRule inspects a named instance's resolved facts and returns any
+// violations it finds.
+type InstanceRule func(name string, facts InstanceFacts) []Violation
+
+// IngressRuleCheck inspects a named security group's resolved ingress rules
+// and returns any violations it finds.
+type IngressRuleCheck func(name string, rules []IngressRule) []Violation
+
+// RequireNameTag fails unless the instance carries a non-empty Name tag.
+func RequireNameTag(name string, facts InstanceFacts) []Violation {
+	if facts.Tags["Name"] == "" {
+		return []Violation{{
+			Rule:     "require-name-tag",
+			Resource: name,
+			Message:  "instance must have a non-empty Name tag",
+		}}
+	}
+	return nil
+}
+
+// ForbidUserData fails if the instance carries cloud-init UserData.
+func ForbidUserData(name string, facts InstanceFacts) []Violation {
+	if facts.UserData != "" {
+		return []Violation{{
+			Rule:     "forbid-user-data",
+			Resource: name,
+			Message:  "instance must not set UserData",
+		}}
+	}
+	return nil
+}
+
+// ForbidPublicSSH fails if any ingress rule opens port 22 to the whole
+// internet, over either IPv4 or IPv6.
+func ForbidPublicSSH(name string, rules []IngressRule) []Violation {
+	var violations []Violation
+	for _, r := range rules {
+		// Protocol "-1" opens every port regardless of FromPort/ToPort.
+		if r.Protocol != "-1" && (r.FromPort > 22 || r.ToPort < 22) {
+			continue
+		}
+		for _, cidr := range r.CidrBlocks {
+			if cidr == "0.0.0.0/0" {
+				violations = append(violations, Violation{
+					Rule:     "forbid-public-ssh",
+					Resource: name,
+					Message:  "security group must not open port 22 to 0.0.0.0/0",
+				})
+			}
+		}
+		for _, cidr := range r.Ipv6CidrBlocks {
+			if cidr == "::/0" {
+				violations = append(violations, Violation{
+					Rule:     "forbid-public-ssh",
+					Resource: name,
+					Message:  "security group must not open port 22 to ::/0",
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// DefaultInstanceRules is the standard set of instance rules applied to
+// every ec2.Instance.
+var DefaultInstanceRules = []InstanceRule{RequireNameTag, ForbidUserData}
+
+// DefaultIngressRules is the standard set of ingress rules applied to every
+// ec2.SecurityGroup.
+var DefaultIngressRules = []IngressRuleCheck{ForbidPublicSSH}
+
+// CheckInstance runs the given rules (DefaultInstanceRules if none are
+// supplied) against a named instance's resolved facts.
+func CheckInstance(name string, facts InstanceFacts, rules ...InstanceRule) []Violation {
+	if len(rules) == 0 {
+		rules = DefaultInstanceRules
+	}
+	var violations []Violation
+	for _, rule := range rules {
+		violations = append(violations, rule(name, facts)...)
+	}
+	return violations
+}
+
+// CheckIngress runs the given rules (DefaultIngressRules if none are
+// supplied) against a named security group's resolved ingress rules.
+func CheckIngress(name string, ingress []IngressRule, rules ...IngressRuleCheck) []Violation {
+	if len(rules) == 0 {
+		rules = DefaultIngressRules
+	}
+	var violations []Violation
+	for _, rule := range rules {
+		violations = append(violations, rule(name, ingress)...)
+	}
+	return violations
+}
@@ -0,0 +1,94 @@
+package policy
+
+import "testing"
+
+func TestRequireNameTag(t *testing.T) {
+	cases := []struct {
+		name    string
+		facts   InstanceFacts
+		wantLen int
+	}{
+		{"tagged", InstanceFacts{Tags: map[string]string{"Name": "webserver"}}, 0},
+		{"untagged", InstanceFacts{Tags: map[string]string{}}, 1},
+		{"nil-tags", InstanceFacts{}, 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := RequireNameTag("web-server-www", c.facts); len(got) != c.wantLen {
+				t.Errorf("RequireNameTag() = %v, want %d violation(s)", got, c.wantLen)
+			}
+		})
+	}
+}
+
+func TestForbidUserData(t *testing.T) {
+	cases := []struct {
+		name    string
+		facts   InstanceFacts
+		wantLen int
+	}{
+		{"no-user-data", InstanceFacts{}, 0},
+		{"has-user-data", InstanceFacts{UserData: "#!/bin/bash\necho hi"}, 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ForbidUserData("web-server-www", c.facts); len(got) != c.wantLen {
+				t.Errorf("ForbidUserData() = %v, want %d violation(s)", got, c.wantLen)
+			}
+		})
+	}
+}
+
+func TestForbidPublicSSH(t *testing.T) {
+	cases := []struct {
+		name    string
+		rules   []IngressRule
+		wantLen int
+	}{
+		{
+			name: "http-only",
+			rules: []IngressRule{
+				{Protocol: "tcp", FromPort: 80, ToPort: 80, CidrBlocks: []string{"0.0.0.0/0"}},
+			},
+			wantLen: 0,
+		},
+		{
+			name: "ssh-scoped-to-office",
+			rules: []IngressRule{
+				{Protocol: "tcp", FromPort: 22, ToPort: 22, CidrBlocks: []string{"10.0.0.0/8"}},
+			},
+			wantLen: 0,
+		},
+		{
+			name: "ssh-open-to-world-v4",
+			rules: []IngressRule{
+				{Protocol: "tcp", FromPort: 22, ToPort: 22, CidrBlocks: []string{"0.0.0.0/0"}},
+			},
+			wantLen: 1,
+		},
+		{
+			name: "ssh-open-to-world-v6",
+			rules: []IngressRule{
+				{Protocol: "tcp", FromPort: 22, ToPort: 22, Ipv6CidrBlocks: []string{"::/0"}},
+			},
+			wantLen: 1,
+		},
+		{
+			name: "port-range-includes-22",
+			rules: []IngressRule{
+				{Protocol: "tcp", FromPort: 0, ToPort: 65535, CidrBlocks: []string{"0.0.0.0/0"}},
+			},
+			wantLen: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ForbidPublicSSH("web-secgrp", c.rules); len(got) != c.wantLen {
+				t.Errorf("ForbidPublicSSH() = %v, want %d violation(s)", got, c.wantLen)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/application"
+	"github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/network"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Config is the full set of inputs needed to stand up the stack.
+type Config struct {
+	Network     network.Config
+	Application application.Config
+}
+
+// Stack wires the network and application layers together: the
+// application's instance and load balancer are deployed into the subnets
+// the network layer creates.
+type Stack struct {
+	Network     *network.Network
+	Application *application.Application
+}
+
+// NewStack builds the network layer and then the application layer inside
+// it.
+func NewStack(ctx *pulumi.Context, name string, cfg Config) (*Stack, error) {
+	net, err := network.New(ctx, name, cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := application.New(ctx, name, net, cfg.Application)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stack{
+		Network:     net,
+		Application: app,
+	}, nil
+}
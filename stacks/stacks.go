@@ -0,0 +1,109 @@
+// Package stacks resolves per-environment overrides (instance type, AMI
+// filter, tag prefix, ingress rules) for a named Pulumi stack, and
+// normalizes what every environment exports.
+package stacks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	appconfig "github.com/Graham-Beer/Understanding-Pulumi-Unit-Tests/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	pulumiconfig "github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfig is the set of per-environment overrides a named stack can
+// supply. A zero field means "use the program's default".
+type EnvConfig struct {
+	InstanceType string                  `yaml:"instance_type"`
+	AmiFilter    string                  `yaml:"ami_filter"`
+	AmiOwner     string                  `yaml:"ami_owner"`
+	TagPrefix    string                  `yaml:"tag_prefix"`
+	Ingress      []appconfig.IngressRule `yaml:"ingress"`
+	IngressFile  string                  `yaml:"ingress_file"`
+}
+
+// LoadFile reads the fallback stacks/<name>.yaml file for the named
+// environment. A missing file is not an error: it just means the
+// environment has no file-based overrides. If the file sets ingress_file,
+// its ingress rules are loaded from that path via config.LoadIngressRules
+// instead of the inline ingress list.
+func LoadFile(name string) (EnvConfig, error) {
+	path := filepath.Join("stacks", name+".yaml")
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return EnvConfig{}, nil
+	}
+	if err != nil {
+		return EnvConfig{}, fmt.Errorf("stacks: reading %s: %w", path, err)
+	}
+
+	var cfg EnvConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return EnvConfig{}, fmt.Errorf("stacks: parsing %s: %w", path, err)
+	}
+
+	if cfg.IngressFile != "" {
+		rules, err := appconfig.LoadIngressRules(cfg.IngressFile)
+		if err != nil {
+			return EnvConfig{}, fmt.Errorf("stacks: %s: %w", path, err)
+		}
+		cfg.Ingress = rules
+		return cfg, nil
+	}
+
+	for i, rule := range cfg.Ingress {
+		if err := rule.Validate(); err != nil {
+			return EnvConfig{}, fmt.Errorf("stacks: ingress rule %d in %s: %w", i, path, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Resolve returns the overrides for the named environment. Pulumi stack
+// config (`pulumi config set <key> <value>` under the current stack) takes
+// precedence over stacks/<name>.yaml, which in turn takes precedence over
+// the program's own defaults.
+func Resolve(ctx *pulumi.Context, name string) (EnvConfig, error) {
+	cfg, err := LoadFile(name)
+	if err != nil {
+		return EnvConfig{}, err
+	}
+
+	c := pulumiconfig.New(ctx, "")
+	if v := c.Get("instanceType"); v != "" {
+		cfg.InstanceType = v
+	}
+	if v := c.Get("amiFilter"); v != "" {
+		cfg.AmiFilter = v
+	}
+	if v := c.Get("amiOwner"); v != "" {
+		cfg.AmiOwner = v
+	}
+	if v := c.Get("tagPrefix"); v != "" {
+		cfg.TagPrefix = v
+	}
+
+	return cfg, nil
+}
+
+// Outputs is the normalized set of values every environment's stack
+// exports, regardless of its overrides.
+type Outputs struct {
+	PublicIP         pulumi.StringOutput
+	PublicDNS        pulumi.StringOutput
+	SecurityGroupArn pulumi.StringOutput
+	InstanceID       pulumi.IDOutput
+}
+
+// Export publishes o under the stack's standard output names.
+func (o Outputs) Export(ctx *pulumi.Context) {
+	ctx.Export("publicIp", o.PublicIP)
+	ctx.Export("publicDns", o.PublicDNS)
+	ctx.Export("securityGroupArn", o.SecurityGroupArn)
+	ctx.Export("instanceId", o.InstanceID)
+}
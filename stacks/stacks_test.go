@@ -0,0 +1,85 @@
+package stacks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// withWorkingDir chdirs into dir for the duration of the test, since
+// LoadFile resolves stacks/<name>.yaml relative to the current directory.
+func withWorkingDir(t *testing.T, dir string) {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "stacks"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	yamlContent := `
+instance_type: t3.large
+tag_prefix: prod
+ingress:
+  - protocol: tcp
+    from_port: 443
+    to_port: 443
+    cidr_blocks: ["0.0.0.0/0"]
+    description: https
+`
+	if err := os.WriteFile(filepath.Join(dir, "stacks", "prod.yaml"), []byte(yamlContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withWorkingDir(t, dir)
+
+	cfg, err := LoadFile("prod")
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.InstanceType != "t3.large" || cfg.TagPrefix != "prod" || len(cfg.Ingress) != 1 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	withWorkingDir(t, t.TempDir())
+
+	cfg, err := LoadFile("doesnotexist")
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, EnvConfig{}) {
+		t.Errorf("expected zero value, got %+v", cfg)
+	}
+}
+
+func TestLoadFileInvalidIngress(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "stacks"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	badYAML := `
+ingress:
+  - protocol: ftp
+    from_port: 21
+    to_port: 21
+    cidr_blocks: ["0.0.0.0/0"]
+`
+	if err := os.WriteFile(filepath.Join(dir, "stacks", "broken.yaml"), []byte(badYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withWorkingDir(t, dir)
+
+	if _, err := LoadFile("broken"); err == nil {
+		t.Fatal("expected a validation error for an unsupported protocol")
+	}
+}